@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ColumnsConfig names the CSV columns the parser looks for.
+type ColumnsConfig struct {
+	ItemID   string `json:"item_id" yaml:"item_id"`
+	Name     string `json:"name" yaml:"name"`
+	Category string `json:"category" yaml:"category"`
+}
+
+// ProfileConfig describes one named filter/output profile. A single config
+// file can define several, so one run can emit separate output subdirectories
+// for different filter sets (e.g. "pets-only", "housing").
+type ProfileConfig struct {
+	Name               string   `json:"name" yaml:"name"`
+	OutputSubdir       string   `json:"output_subdir" yaml:"output_subdir"`
+	IncludedCategories []string `json:"included_categories" yaml:"included_categories"`
+	ExcludeWords       []string `json:"exclude_words" yaml:"exclude_words"`
+	IncludeWords       []string `json:"include_words" yaml:"include_words"`
+}
+
+// Config is the top-level shape of a -config file.
+type Config struct {
+	Columns  ColumnsConfig   `json:"columns" yaml:"columns"`
+	Profiles []ProfileConfig `json:"profiles" yaml:"profiles"`
+}
+
+// defaultColumns mirrors the column names the tool has always looked for.
+var defaultColumns = ColumnsConfig{ItemID: "Item ID", Name: "Name", Category: "Category"}
+
+// defaultConfig reproduces the previous hardcoded behavior as a single
+// "default" profile, used when no -config file is supplied.
+func defaultConfig() Config {
+	return Config{
+		Columns: defaultColumns,
+		Profiles: []ProfileConfig{
+			{
+				Name:               "default",
+				IncludedCategories: includedCategories,
+				ExcludeWords:       excludeWords,
+			},
+		},
+	}
+}
+
+// loadConfig reads a YAML or JSON config file, chosen by file extension.
+func loadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	var cfg Config
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("could not parse YAML config: %w", err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("could not parse JSON config: %w", err)
+		}
+	default:
+		return Config{}, fmt.Errorf("unsupported config extension %q (use .yaml, .yml or .json)", ext)
+	}
+
+	if cfg.Columns.ItemID == "" {
+		cfg.Columns.ItemID = defaultColumns.ItemID
+	}
+	if cfg.Columns.Name == "" {
+		cfg.Columns.Name = defaultColumns.Name
+	}
+	if cfg.Columns.Category == "" {
+		cfg.Columns.Category = defaultColumns.Category
+	}
+	if len(cfg.Profiles) == 0 {
+		return Config{}, fmt.Errorf("config must define at least one profile")
+	}
+
+	return cfg, nil
+}
+
+// compiledFilter holds a profile's filter criteria compiled for matching.
+type compiledFilter struct {
+	profile            ProfileConfig
+	includedCategories map[string]bool
+	excludeWords       []*regexp.Regexp
+	includeWords       []*regexp.Regexp
+}
+
+// compileFilter compiles a profile's category set and word patterns.
+func compileFilter(profile ProfileConfig) (compiledFilter, error) {
+	included := make(map[string]bool, len(profile.IncludedCategories))
+	for _, c := range profile.IncludedCategories {
+		included[c] = true
+	}
+
+	exclude, err := compilePatterns(profile.ExcludeWords)
+	if err != nil {
+		return compiledFilter{}, fmt.Errorf("profile %q: invalid exclude_words: %w", profile.Name, err)
+	}
+
+	include, err := compilePatterns(profile.IncludeWords)
+	if err != nil {
+		return compiledFilter{}, fmt.Errorf("profile %q: invalid include_words: %w", profile.Name, err)
+	}
+
+	return compiledFilter{
+		profile:            profile,
+		includedCategories: included,
+		excludeWords:       exclude,
+		includeWords:       include,
+	}, nil
+}
+
+// compilePatterns compiles each word as a regular expression.
+func compilePatterns(words []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(words))
+	for _, w := range words {
+		re, err := regexp.Compile(w)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", w, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
+}
+
+// matches reports whether category/name passes this filter's included
+// category, exclude-word and include-word (allowlist) criteria.
+func (f compiledFilter) matches(category, name string) bool {
+	if !f.includedCategories[category] {
+		return false
+	}
+	for _, re := range f.excludeWords {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	if len(f.includeWords) > 0 {
+		allowed := false
+		for _, re := range f.includeWords {
+			if re.MatchString(name) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
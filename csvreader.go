@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// utf8BOM is the three-byte UTF-8 byte order mark some spreadsheet tools
+// (notably Excel) prepend to exported CSVs.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// CSVReaderOptions controls how newCSVReader opens and decodes a CSV file.
+type CSVReaderOptions struct {
+	Encoding        string // "utf8" (default), "utf16le", "gbk", "latin1"
+	Delimiter       rune   // field delimiter, defaults to ','
+	LazyQuotes      bool
+	FieldsPerRecord int // 0 = first row sets it, negative = no check
+}
+
+// newCSVReader builds a csv.Reader over file according to opts, stripping a
+// leading UTF-8 BOM and decoding non-UTF-8 encodings before the bytes reach
+// encoding/csv.
+func newCSVReader(file io.Reader, opts CSVReaderOptions) (*csv.Reader, error) {
+	decoded, err := decodeReader(file, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(stripBOM(decoded))
+
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+	reader.LazyQuotes = opts.LazyQuotes
+	reader.FieldsPerRecord = opts.FieldsPerRecord
+
+	return reader, nil
+}
+
+// parseDelimiter turns a -delimiter flag value (expected to be a single
+// rune, e.g. "," ";" or a literal tab) into the rune csv.Reader.Comma wants.
+func parseDelimiter(s string) (rune, error) {
+	runes := []rune(s)
+	switch len(runes) {
+	case 0:
+		return ',', nil
+	case 1:
+		return runes[0], nil
+	default:
+		return 0, fmt.Errorf("delimiter must be a single character, got %q", s)
+	}
+}
+
+// decodeReader wraps r in a transform.Reader for the named encoding. An
+// empty name or "utf8" leaves r untouched.
+func decodeReader(r io.Reader, encodingName string) (io.Reader, error) {
+	switch encodingName {
+	case "", "utf8":
+		return r, nil
+	case "utf16le":
+		return transform.NewReader(r, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()), nil
+	case "gbk":
+		return transform.NewReader(r, simplifiedchinese.GBK.NewDecoder()), nil
+	case "latin1":
+		return transform.NewReader(r, charmap.ISO8859_1.NewDecoder()), nil
+	default:
+		return nil, fmt.Errorf("unsupported -encoding %q (want utf8, utf16le, gbk, or latin1)", encodingName)
+	}
+}
+
+// stripBOM peeks the first three bytes of r and discards them if they are
+// the UTF-8 byte order mark, so the first header column doesn't come back
+// with a leading BOM rune glued onto "Item ID".
+func stripBOM(r io.Reader) io.Reader {
+	buffered := bufio.NewReader(r)
+	peeked, err := buffered.Peek(len(utf8BOM))
+	if err == nil && bytes.Equal(peeked, utf8BOM) {
+		buffered.Discard(len(utf8BOM))
+	}
+	return buffered
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// reservedOutputFilenames are files exportItems writes that aren't
+// per-category item maps and should be skipped when diffing a directory.
+var reservedOutputFilenames = map[string]bool{
+	"allitems.json": true,
+	"index.json":    true,
+	"changes.json":  true,
+}
+
+// ItemChange is a single added or removed item in a diff.
+type ItemChange struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// RenamedChange is an item whose ID stayed the same but whose name changed.
+type RenamedChange struct {
+	ID      string `json:"id"`
+	OldName string `json:"old_name"`
+	NewName string `json:"new_name"`
+}
+
+// CategoryDiff holds the added/removed/renamed items for one category.
+type CategoryDiff struct {
+	Category string          `json:"category"`
+	Added    []ItemChange    `json:"added,omitempty"`
+	Removed  []ItemChange    `json:"removed,omitempty"`
+	Renamed  []RenamedChange `json:"renamed,omitempty"`
+}
+
+// DiffResult is the top-level shape of changes.json.
+type DiffResult struct {
+	PreviousDir string         `json:"previous_dir"`
+	CurrentDir  string         `json:"current_dir"`
+	Categories  []CategoryDiff `json:"categories"`
+}
+
+// runDiff compares the newly written outputDir against previousDir and
+// writes changes.json and changes.md describing what was added, removed or
+// renamed per category.
+func runDiff(outputDir, previousDir string) error {
+	oldCategories, err := loadCategoryMaps(previousDir)
+	if err != nil {
+		return fmt.Errorf("could not load previous run %s: %w", previousDir, err)
+	}
+
+	newCategories, err := loadCategoryMaps(outputDir)
+	if err != nil {
+		return fmt.Errorf("could not load current run %s: %w", outputDir, err)
+	}
+
+	result := DiffResult{
+		PreviousDir: previousDir,
+		CurrentDir:  outputDir,
+		Categories:  diffCategoryMaps(oldCategories, newCategories),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling diff: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "changes.json"), jsonData, 0644); err != nil {
+		return fmt.Errorf("error writing changes.json: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, "changes.md"), []byte(renderDiffMarkdown(result)), 0644); err != nil {
+		return fmt.Errorf("error writing changes.md: %w", err)
+	}
+
+	fmt.Printf("Diff against %s written to %s (changes.json, changes.md)\n", previousDir, outputDir)
+	return nil
+}
+
+// loadCategoryMaps reads every per-category JSON file in dir into a map of
+// category name to its ID-to-name item map.
+func loadCategoryMaps(dir string) (map[string]ItemMap, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory %s: %w", dir, err)
+	}
+
+	// Category names are sanitized into filenames (spaces -> underscores),
+	// so recover the real name from index.json when this directory has one.
+	categoryNames := manifestCategoryNames(dir)
+
+	categories := make(map[string]ItemMap)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		if reservedOutputFilenames[entry.Name()] {
+			continue
+		}
+
+		category := strings.TrimSuffix(entry.Name(), ".json")
+		if name, ok := categoryNames[entry.Name()]; ok {
+			category = name
+		}
+
+		itemMap, err := loadItemMap(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		categories[category] = itemMap
+	}
+
+	return categories, nil
+}
+
+// diffCategoryMaps compares old and new category item maps and returns a
+// deterministically sorted (by category, then by ID) list of diffs.
+func diffCategoryMaps(old, new map[string]ItemMap) []CategoryDiff {
+	categoryNames := make([]string, 0, len(old)+len(new))
+	seen := make(map[string]bool)
+	for category := range old {
+		if !seen[category] {
+			seen[category] = true
+			categoryNames = append(categoryNames, category)
+		}
+	}
+	for category := range new {
+		if !seen[category] {
+			seen[category] = true
+			categoryNames = append(categoryNames, category)
+		}
+	}
+	sort.Strings(categoryNames)
+
+	diffs := make([]CategoryDiff, 0, len(categoryNames))
+	for _, category := range categoryNames {
+		diff := diffItemMaps(category, old[category], new[category])
+		if len(diff.Added) > 0 || len(diff.Removed) > 0 || len(diff.Renamed) > 0 {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+// diffItemMaps compares a single category's old and new item maps.
+func diffItemMaps(category string, old, new ItemMap) CategoryDiff {
+	var added, removed []ItemChange
+	var renamed []RenamedChange
+
+	for id, newName := range new {
+		oldName, existed := old[id]
+		if !existed {
+			added = append(added, ItemChange{ID: id, Name: newName})
+		} else if oldName != newName {
+			renamed = append(renamed, RenamedChange{ID: id, OldName: oldName, NewName: newName})
+		}
+	}
+	for id, oldName := range old {
+		if _, stillExists := new[id]; !stillExists {
+			removed = append(removed, ItemChange{ID: id, Name: oldName})
+		}
+	}
+
+	sort.Slice(added, func(i, j int) bool { return lessID(added[i].ID, added[j].ID) })
+	sort.Slice(removed, func(i, j int) bool { return lessID(removed[i].ID, removed[j].ID) })
+	sort.Slice(renamed, func(i, j int) bool { return lessID(renamed[i].ID, renamed[j].ID) })
+
+	return CategoryDiff{Category: category, Added: added, Removed: removed, Renamed: renamed}
+}
+
+// renderDiffMarkdown renders a DiffResult as a human-readable Markdown report.
+func renderDiffMarkdown(result DiffResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Changes vs %s\n\n", result.PreviousDir)
+
+	if len(result.Categories) == 0 {
+		b.WriteString("No changes detected.\n")
+		return b.String()
+	}
+
+	for _, diff := range result.Categories {
+		fmt.Fprintf(&b, "## %s\n\n", diff.Category)
+
+		if len(diff.Added) > 0 {
+			fmt.Fprintf(&b, "### Added (%d)\n\n", len(diff.Added))
+			for _, item := range diff.Added {
+				fmt.Fprintf(&b, "- `%s` %s\n", item.ID, item.Name)
+			}
+			b.WriteString("\n")
+		}
+
+		if len(diff.Removed) > 0 {
+			fmt.Fprintf(&b, "### Removed (%d)\n\n", len(diff.Removed))
+			for _, item := range diff.Removed {
+				fmt.Fprintf(&b, "- `%s` %s\n", item.ID, item.Name)
+			}
+			b.WriteString("\n")
+		}
+
+		if len(diff.Renamed) > 0 {
+			fmt.Fprintf(&b, "### Renamed (%d)\n\n", len(diff.Renamed))
+			for _, item := range diff.Renamed {
+				fmt.Fprintf(&b, "- `%s` %s -> %s\n", item.ID, item.OldName, item.NewName)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
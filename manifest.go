@@ -0,0 +1,96 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// manifestSchemaVersion is bumped whenever the index.json shape changes in a
+// way downstream consumers need to branch on.
+const manifestSchemaVersion = 1
+
+// CategoryManifestEntry describes one category's JSON output file.
+type CategoryManifestEntry struct {
+	Category  string `json:"category"`
+	Filename  string `json:"filename"`
+	ItemCount int    `json:"item_count"`
+	MinID     string `json:"min_id"`
+	MaxID     string `json:"max_id"`
+	SHA256    string `json:"sha256"`
+}
+
+// Manifest is the top-level shape of index.json, modeled on how puzzle/pool
+// bundles publish a single index so downstream consumers (web UI, game
+// client cache) can discover what exists and detect changes without
+// re-hashing every category file themselves.
+type Manifest struct {
+	SchemaVersion int                     `json:"schema_version"`
+	SourceFile    string                  `json:"source_file"`
+	SourceModTime time.Time               `json:"source_mtime"`
+	Categories    []CategoryManifestEntry `json:"categories"`
+}
+
+// buildManifestEntry computes a category's manifest entry from its already
+// ID-sorted items and the JSON bytes written for it.
+func buildManifestEntry(category, filename string, items []Item, jsonData []byte) CategoryManifestEntry {
+	sum := sha256.Sum256(jsonData)
+	return CategoryManifestEntry{
+		Category:  category,
+		Filename:  filename,
+		ItemCount: len(items),
+		MinID:     items[0].ID,
+		MaxID:     items[len(items)-1].ID,
+		SHA256:    hex.EncodeToString(sum[:]),
+	}
+}
+
+// writeManifest writes index.json listing every category's output file
+// alongside metadata about the CSV the run was generated from.
+func writeManifest(outputDir, sourceCSVPath string, entries []CategoryManifestEntry) error {
+	sourceInfo, err := os.Stat(sourceCSVPath)
+	if err != nil {
+		return fmt.Errorf("could not stat source CSV: %w", err)
+	}
+
+	manifest := Manifest{
+		SchemaVersion: manifestSchemaVersion,
+		SourceFile:    filepath.Base(sourceCSVPath),
+		SourceModTime: sourceInfo.ModTime().UTC(),
+		Categories:    entries,
+	}
+
+	jsonData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling manifest: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(outputDir, "index.json"), jsonData, 0644)
+}
+
+// manifestCategoryNames reads dir's index.json, if any, and returns a map
+// from category filename back to its real category name. sanitizeFilename
+// is lossy (spaces become underscores), so callers that need the original
+// category name back - merge and diff - consult the manifest instead of
+// trying to reverse the filename.
+func manifestCategoryNames(dir string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		return nil
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+
+	names := make(map[string]string, len(manifest.Categories))
+	for _, entry := range manifest.Categories {
+		names[entry.Filename] = entry.Category
+	}
+	return names
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runMerge is the entry point for "-mode merge": it reads a directory of
+// per-category JSON files (or a single allitems.json) and reserializes them
+// back into a CSV with the original Item ID,Name,Category schema.
+func runMerge(inputPath, outputPath string, delimiter rune, includeHeader bool) error {
+	items, err := loadItemsForMerge(inputPath)
+	if err != nil {
+		return fmt.Errorf("could not load items for merge: %w", err)
+	}
+
+	sortItemsByID(items)
+
+	if err := writeItemsCSV(outputPath, items, delimiter, includeHeader); err != nil {
+		return fmt.Errorf("could not write merged CSV: %w", err)
+	}
+
+	fmt.Printf("Merged %d items from %s into %s\n", len(items), inputPath, outputPath)
+	return nil
+}
+
+// loadItemsForMerge loads items from either a single JSON item map or a
+// directory of per-category JSON item maps (named "<category>.json").
+func loadItemsForMerge(inputPath string) ([]Item, error) {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not stat %s: %w", inputPath, err)
+	}
+
+	if !info.IsDir() {
+		itemMap, err := loadItemMap(inputPath)
+		if err != nil {
+			return nil, err
+		}
+		return itemsFromMap(itemMap, ""), nil
+	}
+
+	entries, err := os.ReadDir(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory %s: %w", inputPath, err)
+	}
+
+	// Category names are sanitized into filenames (spaces -> underscores),
+	// so recover the real name from index.json when this directory has one.
+	categoryNames := manifestCategoryNames(inputPath)
+
+	var items []Item
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		if reservedOutputFilenames[entry.Name()] {
+			continue
+		}
+
+		category := strings.TrimSuffix(entry.Name(), ".json")
+		if name, ok := categoryNames[entry.Name()]; ok {
+			category = name
+		}
+
+		itemMap, err := loadItemMap(filepath.Join(inputPath, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, itemsFromMap(itemMap, category)...)
+	}
+
+	return items, nil
+}
+
+// loadItemMap reads and unmarshals an ID-to-name JSON item map.
+func loadItemMap(path string) (ItemMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	var itemMap ItemMap
+	if err := json.Unmarshal(data, &itemMap); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return itemMap, nil
+}
+
+// itemsFromMap expands an ID-to-name map back into Items, stamping each
+// with the given category.
+func itemsFromMap(itemMap ItemMap, category string) []Item {
+	items := make([]Item, 0, len(itemMap))
+	for id, name := range itemMap {
+		items = append(items, Item{ID: id, Name: name, Category: category})
+	}
+	return items
+}
+
+// writeItemsCSV writes items as a CSV with the Item ID,Name,Category schema.
+func writeItemsCSV(path string, items []Item, delimiter rune, includeHeader bool) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if delimiter != 0 {
+		writer.Comma = delimiter
+	}
+
+	if includeHeader {
+		if err := writer.Write([]string{"Item ID", "Name", "Category"}); err != nil {
+			return fmt.Errorf("could not write header: %w", err)
+		}
+	}
+
+	for _, item := range items {
+		if err := writer.Write([]string{item.ID, item.Name, item.Category}); err != nil {
+			return fmt.Errorf("could not write row for item %s: %w", item.ID, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -1,11 +1,9 @@
 package main
 
 import (
-	"encoding/csv"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -13,7 +11,7 @@ import (
 	"strings"
 )
 
-// Configuration for included categories and excluded terms
+// Built-in defaults used when no -config file is supplied; see defaultConfig.
 var (
 	includedCategories = []string{"Pet", "House Floor", "House Wallpaper", "House", "NPC Skin"}
 	excludeWords       = []string{"Quest", "DONT", "Don't", "Bug"}
@@ -31,40 +29,122 @@ type ItemMap map[string]string
 
 func main() {
 	// Setup command-line flags
-	inputFile := flag.String("file", "", "Path to the CSV file")
-	outputDir := flag.String("outdir", "output", "Directory to save the JSON files")
+	inputFile := flag.String("file", "", "Path to the CSV file (-mode split) or JSON file/directory (-mode merge)")
+	outputDir := flag.String("outdir", "output", "Directory to save the JSON files (-mode split)")
+	configPath := flag.String("config", "", "Path to a YAML/JSON config file defining columns and profiles")
+	encoding := flag.String("encoding", "utf8", "Source CSV text encoding: utf8, utf16le, gbk, or latin1")
+	delimiter := flag.String("delimiter", ",", "CSV field delimiter")
+	lazyQuotes := flag.Bool("lazy-quotes", false, "Allow improperly quoted fields (csv.Reader.LazyQuotes)")
+	fieldsPerRecord := flag.Int("fields-per-record", 0, "Expected fields per record (0 = infer from header, negative = no check)")
+	mode := flag.String("mode", "split", "Pipeline direction: split (CSV -> JSON, default) or merge (JSON -> CSV)")
+	mergeOut := flag.String("merge-out", "items.csv", "Output CSV path for -mode merge")
+	diffAgainst := flag.String("diff", "", "Previous -outdir to compare against; writes changes.json/changes.md")
+	header := flag.Bool("header", true, "Include a header row when writing CSV for -mode merge")
+	workers := flag.Int("workers", 4, "Number of concurrent filter/write workers for -mode split")
 	flag.Parse()
 
 	// Check if input file was provided
 	if *inputFile == "" {
-		fmt.Println("Please provide an input CSV file with -file flag")
+		fmt.Println("Please provide an input file with -file flag")
 		fmt.Println("Example: ./csv_parser -file items.csv [-outdir output_directory]")
+		fmt.Println("Example: ./csv_parser -mode merge -file output/ -merge-out items.csv")
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	// Process the CSV file
-	items, err := processCSV(*inputFile)
+	delimiterRune, err := parseDelimiter(*delimiter)
 	if err != nil {
-		fmt.Printf("Error processing CSV: %v\n", err)
+		fmt.Printf("Error parsing -delimiter: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Sort items by ID
-	sortItemsByID(items)
+	if *mode == "merge" {
+		if err := runMerge(*inputFile, *mergeOut, delimiterRune, *header); err != nil {
+			fmt.Printf("Error merging: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *mode != "split" {
+		fmt.Printf("Unknown -mode %q (want split or merge)\n", *mode)
+		os.Exit(1)
+	}
+
+	readerOpts := CSVReaderOptions{
+		Encoding:        *encoding,
+		Delimiter:       delimiterRune,
+		LazyQuotes:      *lazyQuotes,
+		FieldsPerRecord: *fieldsPerRecord,
+	}
+
+	// Load the filter/schema config, falling back to the built-in defaults
+	// when no -config file is given.
+	cfg := defaultConfig()
+	if *configPath != "" {
+		loaded, err := loadConfig(*configPath)
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		cfg = loaded
+	}
+
+	for _, profile := range cfg.Profiles {
+		filter, err := compileFilter(profile)
+		if err != nil {
+			fmt.Printf("Error compiling profile %q: %v\n", profile.Name, err)
+			os.Exit(1)
+		}
+
+		items, err := processCSV(*inputFile, cfg.Columns, filter, readerOpts, *workers)
+		if err != nil {
+			fmt.Printf("Error processing CSV for profile %q: %v\n", profile.Name, err)
+			os.Exit(1)
+		}
+
+		// Sort items by ID
+		sortItemsByID(items)
 
+		profileDir := *outputDir
+		if subdir := profile.OutputSubdir; subdir != "" {
+			profileDir = filepath.Join(*outputDir, subdir)
+		} else if len(cfg.Profiles) > 1 {
+			profileDir = filepath.Join(*outputDir, sanitizeFilename(profile.Name))
+		}
+
+		categoryCount, err := exportItems(profileDir, *inputFile, items, *workers)
+		if err != nil {
+			fmt.Printf("Error exporting profile %q: %v\n", profile.Name, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\nProfile %q: %d items processed, exported to %d category files in %s\n",
+			profile.Name, len(items), categoryCount, profileDir)
+
+		if *diffAgainst != "" {
+			if err := runDiff(profileDir, *diffAgainst); err != nil {
+				fmt.Printf("Error diffing profile %q: %v\n", profile.Name, err)
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+// exportItems writes allitems.json, one JSON file per category, and an
+// index.json manifest into outputDir, returning the number of category
+// files written. Category files are marshaled and flushed concurrently
+// across up to `workers` goroutines.
+func exportItems(outputDir, sourceCSVPath string, items []Item, workers int) (int, error) {
 	// Create the output directory if it doesn't exist
-	if err := os.MkdirAll(*outputDir, 0755); err != nil {
-		fmt.Printf("Error creating output directory: %v\n", err)
-		os.Exit(1)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return 0, fmt.Errorf("error creating output directory: %w", err)
 	}
 
 	// Convert all items to the map format and save
 	allItemsMap := convertItemsToMap(items)
-	allItemsPath := filepath.Join(*outputDir, "allitems.json")
+	allItemsPath := filepath.Join(outputDir, "allitems.json")
 	if err := saveMapToJSON(allItemsPath, allItemsMap); err != nil {
-		fmt.Printf("Error saving all items: %v\n", err)
-		os.Exit(1)
+		return 0, fmt.Errorf("error saving all items: %w", err)
 	}
 	fmt.Printf("All items saved to %s\n", allItemsPath)
 
@@ -74,121 +154,92 @@ func main() {
 		categoryMap[item.Category] = append(categoryMap[item.Category], item)
 	}
 
-	// Save each category to its own JSON file
-	for category, categoryItems := range categoryMap {
-		// Create a valid filename from the category
-		filename := sanitizeFilename(category) + ".json"
-		categoryPath := filepath.Join(*outputDir, filename)
-		
-		// Convert category items to map and save
-		itemMap := convertItemsToMap(categoryItems)
-		if err := saveMapToJSON(categoryPath, itemMap); err != nil {
-			fmt.Printf("Error saving category %s: %v\n", category, err)
-			continue
-		}
-		fmt.Printf("Category '%s' saved to %s (%d items)\n", category, categoryPath, len(categoryItems))
+	// Save each category to its own JSON file, in sorted category order so
+	// the run (and its manifest) is deterministic.
+	categories := make([]string, 0, len(categoryMap))
+	for category := range categoryMap {
+		categories = append(categories, category)
 	}
+	sort.Strings(categories)
 
-	// Display summary
-	fmt.Printf("\nTotal items processed: %d\n", len(items))
-	fmt.Printf("Items exported to %d category files\n", len(categoryMap))
-}
-
-// processCSV reads and filters items from the CSV file
-func processCSV(filePath string) ([]Item, error) {
-	// Open the CSV file
-	file, err := os.Open(filePath)
+	manifestEntries, err := writeCategoryFilesConcurrently(outputDir, categories, categoryMap, workers)
 	if err != nil {
-		return nil, fmt.Errorf("could not open file: %w", err)
+		return 0, fmt.Errorf("error saving category files: %w", err)
+	}
+	for _, entry := range manifestEntries {
+		fmt.Printf("Category '%s' saved to %s (%d items)\n", entry.Category, filepath.Join(outputDir, entry.Filename), entry.ItemCount)
 	}
-	defer file.Close()
 
-	// Create a new CSV reader
-	reader := csv.NewReader(file)
-	
-	// Read the header row
-	header, err := reader.Read()
+	if err := writeManifest(outputDir, sourceCSVPath, manifestEntries); err != nil {
+		return 0, fmt.Errorf("error writing manifest: %w", err)
+	}
+
+	return len(categoryMap), nil
+}
+
+// writeCategoryFile marshals one category's items and writes them to
+// outputDir, returning the resulting manifest entry.
+func writeCategoryFile(outputDir, category string, categoryItems []Item) (CategoryManifestEntry, error) {
+	filename := sanitizeFilename(category) + ".json"
+	categoryPath := filepath.Join(outputDir, filename)
+
+	itemMap := convertItemsToMap(categoryItems)
+	jsonData, err := json.MarshalIndent(itemMap, "", "  ")
 	if err != nil {
-		return nil, fmt.Errorf("could not read header: %w", err)
+		return CategoryManifestEntry{}, fmt.Errorf("error marshaling category %s: %w", category, err)
+	}
+	if err := os.WriteFile(categoryPath, jsonData, 0644); err != nil {
+		return CategoryManifestEntry{}, fmt.Errorf("error saving category %s: %w", category, err)
 	}
 
-	// Find the column indices
-	var itemIDIndex, nameIndex, categoryIndex int = -1, -1, -1
+	return buildManifestEntry(category, filename, categoryItems, jsonData), nil
+}
+
+// resolveColumnIndices finds the header positions for the configured
+// Item ID, Name and Category columns.
+func resolveColumnIndices(header []string, columns ColumnsConfig) (itemIDIndex, nameIndex, categoryIndex int, err error) {
+	itemIDIndex, nameIndex, categoryIndex = -1, -1, -1
 	for i, column := range header {
 		trimmedColumn := strings.TrimSpace(column)
-		if trimmedColumn == "Item ID" {
+		if trimmedColumn == columns.ItemID {
 			itemIDIndex = i
-		} else if trimmedColumn == "Name" {
+		} else if trimmedColumn == columns.Name {
 			nameIndex = i
-		} else if trimmedColumn == "Category" {
+		} else if trimmedColumn == columns.Category {
 			categoryIndex = i
 		}
 	}
 
-	// Verify that we found all required columns
 	if itemIDIndex == -1 || nameIndex == -1 || categoryIndex == -1 {
-		return nil, fmt.Errorf("could not find required columns (Item ID, Name, Category) in the CSV")
+		return -1, -1, -1, fmt.Errorf("could not find required columns (%s, %s, %s) in the CSV", columns.ItemID, columns.Name, columns.Category)
 	}
+	return itemIDIndex, nameIndex, categoryIndex, nil
+}
 
-	// Process each row and filter according to criteria
-	var items []Item
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("error reading row: %w", err)
-		}
-
-		// Skip if row doesn't have enough columns
-		if len(record) <= max(itemIDIndex, nameIndex, categoryIndex) {
-			continue
-		}
-
-		// Get field values
-		itemID := strings.TrimSpace(record[itemIDIndex])
-		name := strings.TrimSpace(record[nameIndex])
-		category := strings.TrimSpace(record[categoryIndex])
-
-		// Skip if any field is empty
-		if itemID == "" || name == "" || category == "" {
-			continue
-		}
+// itemFromRecord extracts and filters a single CSV record into an Item,
+// reporting ok=false for rows that are short, blank, or filtered out.
+func itemFromRecord(record []string, itemIDIndex, nameIndex, categoryIndex int, filter compiledFilter) (Item, bool) {
+	// Skip if row doesn't have enough columns
+	if len(record) <= max(itemIDIndex, nameIndex, categoryIndex) {
+		return Item{}, false
+	}
 
-		// Check if category is in the included list
-		categoryIncluded := false
-		for _, includedCategory := range includedCategories {
-			if category == includedCategory {
-				categoryIncluded = true
-				break
-			}
-		}
-		if !categoryIncluded {
-			continue
-		}
+	// Get field values
+	itemID := strings.TrimSpace(record[itemIDIndex])
+	name := strings.TrimSpace(record[nameIndex])
+	category := strings.TrimSpace(record[categoryIndex])
 
-		// Check if name contains any excluded words
-		nameContainsExcluded := false
-		for _, word := range excludeWords {
-			if strings.Contains(name, word) {
-				nameContainsExcluded = true
-				break
-			}
-		}
-		if nameContainsExcluded {
-			continue
-		}
+	// Skip if any field is empty
+	if itemID == "" || name == "" || category == "" {
+		return Item{}, false
+	}
 
-		// Add the item to our results
-		items = append(items, Item{
-			ID:       itemID,
-			Name:     name,
-			Category: category,
-		})
+	// Apply the profile's category/exclude/include filter
+	if !filter.matches(category, name) {
+		return Item{}, false
 	}
 
-	return items, nil
+	return Item{ID: itemID, Name: name, Category: category}, true
 }
 
 // convertItemsToMap converts a slice of items to a map with ID as key and Name as value
@@ -215,20 +266,26 @@ func saveMapToJSON(filePath string, itemMap ItemMap) error {
 // sortItemsByID sorts the items by their ID
 func sortItemsByID(items []Item) {
 	sort.Slice(items, func(i, j int) bool {
-		// Try to convert to integers for numeric comparison
-		idI, errI := strconv.Atoi(items[i].ID)
-		idJ, errJ := strconv.Atoi(items[j].ID)
-		
-		// If both can be converted to integers, compare numerically
-		if errI == nil && errJ == nil {
-			return idI < idJ
-		}
-		
-		// Otherwise, compare as strings
-		return items[i].ID < items[j].ID
+		return lessID(items[i].ID, items[j].ID)
 	})
 }
 
+// lessID compares two item IDs, preferring numeric comparison when both
+// parse as integers and falling back to a plain string comparison otherwise.
+func lessID(a, b string) bool {
+	// Try to convert to integers for numeric comparison
+	idA, errA := strconv.Atoi(a)
+	idB, errB := strconv.Atoi(b)
+
+	// If both can be converted to integers, compare numerically
+	if errA == nil && errB == nil {
+		return idA < idB
+	}
+
+	// Otherwise, compare as strings
+	return a < b
+}
+
 // sanitizeFilename creates a valid filename from a string
 func sanitizeFilename(name string) string {
 	// Replace spaces with underscores
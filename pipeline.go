@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// processCSV streams filePath's rows through a producer goroutine, a pool
+// of filter workers, and a single accumulator, rather than filtering the
+// whole file synchronously. This keeps peak memory bounded by the channel
+// buffers instead of the number of in-flight rows, which matters once the
+// source CSV runs into the hundreds of MB.
+func processCSV(filePath string, columns ColumnsConfig, filter compiledFilter, readerOpts CSVReaderOptions, workers int) ([]Item, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %w", err)
+	}
+	defer file.Close()
+
+	// Create a new CSV reader, stripping any BOM and decoding the
+	// configured source encoding before the bytes reach encoding/csv.
+	reader, err := newCSVReader(file, readerOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read header: %w", err)
+	}
+
+	itemIDIndex, nameIndex, categoryIndex, err := resolveColumnIndices(header, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	const channelDepth = 256
+	records := make(chan []string, channelDepth)
+	filtered := make(chan Item, channelDepth)
+	readErr := make(chan error, 1)
+
+	// Producer: the only goroutine that calls reader.Read(), since
+	// csv.Reader is not safe for concurrent use.
+	go func() {
+		defer close(records)
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				readErr <- fmt.Errorf("error reading row: %w", err)
+				return
+			}
+			records <- record
+		}
+	}()
+
+	// Filter workers: fan out the CPU-bound column extraction and
+	// exclude/include matching across -workers goroutines.
+	var workerWG sync.WaitGroup
+	workerWG.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer workerWG.Done()
+			for record := range records {
+				if item, ok := itemFromRecord(record, itemIDIndex, nameIndex, categoryIndex, filter); ok {
+					filtered <- item
+				}
+			}
+		}()
+	}
+	go func() {
+		workerWG.Wait()
+		close(filtered)
+	}()
+
+	// Single accumulator: owns the result slice, so no locking is needed.
+	var items []Item
+	for item := range filtered {
+		items = append(items, item)
+	}
+
+	select {
+	case err := <-readErr:
+		return nil, err
+	default:
+	}
+
+	return items, nil
+}
+
+// writeCategoryFilesConcurrently marshals and writes each category's JSON
+// file using up to `workers` goroutines, returning one manifest entry per
+// category in the same order as categories.
+func writeCategoryFilesConcurrently(outputDir string, categories []string, categoryMap map[string][]Item, workers int) ([]CategoryManifestEntry, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	entries := make([]CategoryManifestEntry, len(categories))
+	errs := make([]error, len(categories))
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	wg.Add(len(categories))
+
+	for i, category := range categories {
+		i, category := i, category
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			entry, err := writeCategoryFile(outputDir, category, categoryMap[category])
+			entries[i] = entry
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}